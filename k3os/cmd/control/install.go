@@ -1,15 +1,25 @@
 package control
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 	"syscall"
 
 	"github.com/niusmallnan/k3os/pkg/util"
 
+	"github.com/rancher/k3os/config"
+	"github.com/rancher/k3os/pkg/config/verify"
+	"github.com/rancher/k3os/pkg/image"
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -53,20 +63,70 @@ var installCommand = cli.Command{
 			Name:  "debug",
 			Usage: "run installer with debug output",
 		},
+		cli.StringFlag{
+			Name:  "cloud-config-sig",
+			Usage: "detached signature (minisign, ssh-sig or cosign bundle) over the cloud-config, verified before install",
+		},
+		cli.StringFlag{
+			Name:  "cloud-config-pubkey",
+			Usage: "public key (a PEM public key for cosign bundles) used to verify -cloud-config-sig",
+		},
+		cli.StringFlag{
+			Name:  "cloud-config-ca",
+			Usage: "PEM CA bundle, or sha256:<hex> SPKI pin, used instead of the system trust store to fetch an https:// cloud-config",
+		},
+		cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "with a declarative k3os.install.partitions layout, print the partitioning plan instead of running it",
+		},
+		cli.StringFlag{
+			Name:  "output-format",
+			Value: "disk",
+			Usage: "disk, iso, qcow2, raw, pxe",
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "destination path for --output-format other than disk",
+		},
 	},
 }
 
 func installAction(c *cli.Context) error {
 	installType := c.String("install-type")
 	cloudConfig := c.String("cloud-config")
+	cloudConfigSig := c.String("cloud-config-sig")
+	cloudConfigPubkey := c.String("cloud-config-pubkey")
+	cloudConfigCA := c.String("cloud-config-ca")
 	installDevice := c.String("device")
+	outputFormat := c.String("output-format")
+	output := c.String("output")
 	rebootFlag := !c.Bool("no-reboot")
 	forceFlag := c.Bool("force")
 	//TODO: debug for output
 
-	if installDevice == "" {
+	configurator, ok := image.Configurators[outputFormat]
+	if !ok {
+		logrus.Fatalf("unknown -output-format %q", outputFormat)
+	}
+
+	tlsConfig, err := verify.TLSConfig(cloudConfigCA)
+	if err != nil {
+		logrus.Fatalf("invalid -cloud-config-ca: %v", err)
+	}
+
+	if outputFormat == "disk" && installDevice == "" {
 		logrus.Fatal("can not proceed without -d <dev> specified")
 	}
+	if outputFormat != "disk" && output == "" {
+		logrus.Fatalf("-output is required for -output-format=%s", outputFormat)
+	}
+	if cloudConfigSig != "" && cloudConfigPubkey == "" {
+		// Without a pinned key, the cosign-bundle scheme falls back to
+		// whatever certificate is embedded in the bundle itself - i.e.
+		// attacker-controlled if the attacker can substitute the
+		// signature file. Refuse rather than let that rubber-stamp it.
+		logrus.Fatal("-cloud-config-pubkey is required when -cloud-config-sig is set")
+	}
 
 	if cloudConfig == "" {
 		logrus.Warn("cloud-config not provided: you might need to provide cloud-config on boot with k3os.ssh.authorized_keys")
@@ -84,13 +144,13 @@ func installAction(c *cli.Context) error {
 		return nil
 	}
 
-	installBootScript := fmt.Sprintf("/usr/sbin/k3os-install-%s", installType)
-	if err := util.RunScript(installBootScript, installDevice); err != nil {
-		logrus.Fatalf("failed to install boot things to disk, %v", err)
-	}
-
 	if strings.HasPrefix(cloudConfig, "http://") || strings.HasPrefix(cloudConfig, "https://") {
-		if err := util.HTTPDownloadToFile(cloudConfig, UserConfigTempFile); err != nil {
+		if tlsConfig != nil {
+			err = downloadToFile(cloudConfig, UserConfigTempFile, tlsConfig)
+		} else {
+			err = util.HTTPDownloadToFile(cloudConfig, UserConfigTempFile)
+		}
+		if err != nil {
 			logrus.Fatalf("failed to get cloud-config via http(s): %s", cloudConfig)
 		}
 	} else {
@@ -98,14 +158,194 @@ func installAction(c *cli.Context) error {
 			logrus.Fatalf("failed to copy cloud-config: %s", cloudConfig)
 		}
 	}
-	if err := util.RunScript(InstallConfigScript, UserConfigTempFile); err != nil {
-		logrus.Fatalf("failed to install config to disk, %v", err)
+
+	if cloudConfigSig != "" {
+		if err := verifyConfigFile(UserConfigTempFile, cloudConfigSig, cloudConfigPubkey, tlsConfig); err != nil {
+			os.Remove(UserConfigTempFile)
+			logrus.Fatalf("cloud-config failed signature verification: %v", err)
+		}
+	}
+
+	if err := normalizeConfigFile(UserConfigTempFile); err != nil {
+		logrus.Fatalf("failed to normalize cloud-config: %v", err)
+	}
+
+	if err := validateConfigFile(UserConfigTempFile); err != nil {
+		os.Remove(UserConfigTempFile)
+		logrus.Fatalf("cloud-config failed validation: %v", err)
+	}
+
+	partitions, err := readPartitions(UserConfigTempFile)
+	if err != nil {
+		logrus.Fatalf("failed to read partition layout from cloud-config: %v", err)
+	}
+
+	ctx := &image.Context{
+		Device:      installDevice,
+		InstallType: installType,
+		Partitions:  partitions,
+		ConfigPath:  UserConfigTempFile,
+		Output:      output,
+		DryRun:      c.Bool("dry-run"),
+	}
+	if err := configurator.Configure(ctx); err != nil {
+		logrus.Fatalf("failed to configure %s output, %v", outputFormat, err)
+	}
+
+	if outputFormat == "disk" {
+		if ctx.DryRun {
+			return nil
+		}
+
+		if err := util.RunScript(InstallConfigScript, UserConfigTempFile); err != nil {
+			logrus.Fatalf("failed to install config to disk, %v", err)
+		}
+
+		if rebootFlag || forceFlag {
+			syscall.Sync()
+			syscall.Reboot(int(syscall.LINUX_REBOOT_CMD_RESTART))
+		}
+	} else if !ctx.DryRun {
+		if err := writeChecksum(output); err != nil {
+			logrus.Fatalf("failed to checksum %s, %v", output, err)
+		}
+	}
+
+	return nil
+}
+
+// downloadToFile fetches url into dest using tlsConfig to pin the
+// server, instead of the system trust store util.HTTPDownloadToFile
+// relies on.
+func downloadToFile(url, dest string, tlsConfig *tls.Config) error {
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s fetching %s", resp.Status, url)
+	}
+
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, resp.Body)
+	return err
+}
+
+// verifyConfigFile checks path's detached signature, fetched from the
+// same kind of location (http(s) URL or local file) as sig, against
+// pubkey before the config is allowed anywhere near
+// k3os-install-config.
+func verifyConfigFile(path, sig, pubkey string, tlsConfig *tls.Config) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	sigBytes, err := fetchSignature(sig, tlsConfig)
+	if err != nil {
+		return fmt.Errorf("fetching signature: %v", err)
 	}
 
-	if rebootFlag || forceFlag {
-		syscall.Sync()
-		syscall.Reboot(int(syscall.LINUX_REBOOT_CMD_RESTART))
+	return verify.Verify(data, sigBytes, pubkey)
+}
+
+func fetchSignature(sig string, tlsConfig *tls.Config) ([]byte, error) {
+	if !strings.HasPrefix(sig, "http://") && !strings.HasPrefix(sig, "https://") {
+		return ioutil.ReadFile(sig)
+	}
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := client.Get(sig)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+// validateConfigFile runs the normalized cloud-config at path through
+// config.UnmarshalStrict, so a typo'd key like k3os.ssh.authorised_keys
+// aborts the install with a readable error instead of silently being
+// dropped, and through config.Validate for the field-level checks
+// UnmarshalStrict can't express.
+func validateConfigFile(path string) error {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.UnmarshalStrict(raw)
+	if err != nil {
+		return err
+	}
+
+	if errs := config.Validate(cfg); len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, e := range errs {
+			msgs[i] = e.Error()
+		}
+		return fmt.Errorf("%s", strings.Join(msgs, "; "))
 	}
 
 	return nil
 }
+
+// readPartitions pulls the declarative k3os.install.partitions layout,
+// if any, out of the (already normalized) cloud-config at path.
+func readPartitions(path string) ([]config.PartitionSpec, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg config.CloudConfig
+	if err := yaml.Unmarshal(raw, &cfg); err != nil {
+		return nil, err
+	}
+	return cfg.K3OS.Install.Partitions, nil
+}
+
+// writeChecksum writes a sha256sum-compatible ".sha256" file alongside
+// the artifact a non-disk Configurator produced.
+func writeChecksum(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	sum := fmt.Sprintf("%x  %s\n", h.Sum(nil), filepath.Base(path))
+	return ioutil.WriteFile(path+".sha256", []byte(sum), 0644)
+}
+
+// normalizeConfigFile sniffs the cloud-config at path - native k3os
+// YAML, cloud-init user-data or Ignition JSON - and rewrites it in
+// place as native k3os YAML, so users can bring a config written for
+// Flatcar/CoreOS/RHCOS into k3os unchanged.
+func normalizeConfigFile(path string) error {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	normalized, err := config.Normalize(raw)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, normalized, 0600)
+}
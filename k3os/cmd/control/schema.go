@@ -0,0 +1,23 @@
+package control
+
+import (
+	"fmt"
+
+	"github.com/rancher/k3os/config"
+	"github.com/urfave/cli"
+)
+
+var schemaCommand = cli.Command{
+	Name:   "schema",
+	Usage:  "print the CloudConfig JSON Schema, for editor tooling to validate cloud-config files against",
+	Action: schemaAction,
+}
+
+func schemaAction(c *cli.Context) error {
+	out, err := config.JSONSchema()
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
@@ -0,0 +1,21 @@
+package config
+
+import "fmt"
+
+// ToEnv flattens the parts of cfg that /usr/libexec/k3os/install needs
+// into K3OS_INSTALL_* environment variables, so the installer can run
+// without having to parse the cloud-config itself.
+func ToEnv(cfg CloudConfig) ([]string, error) {
+	install := cfg.K3OS.Install
+
+	env := []string{
+		fmt.Sprintf("K3OS_INSTALL_DEVICE=%s", install.Device),
+		fmt.Sprintf("K3OS_INSTALL_INSTALL_TYPE=%s", install.InstallType),
+		fmt.Sprintf("K3OS_INSTALL_CONFIG_URL=%s", install.ConfigURL),
+		fmt.Sprintf("K3OS_INSTALL_POWER_OFF=%t", install.PowerOff),
+		fmt.Sprintf("K3OS_INSTALL_NO_FORMAT=%t", install.NoFormat),
+		fmt.Sprintf("K3OS_INSTALL_SILENT=%t", install.Silent),
+	}
+
+	return env, nil
+}
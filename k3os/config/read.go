@@ -0,0 +1,49 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ReadConfig loads the k3os config, preferring the copy written to disk
+// by a previous install (LocalConfig) and falling back to the one
+// baked into the running image (SystemConfig). The file may be native
+// k3os YAML, cloud-init user-data or an Ignition config - see
+// ConfigSource.
+//
+// A native k3os document is strict-decoded, so a typo'd key like
+// k3os.ssh.authorised_keys surfaces as an error here instead of being
+// silently dropped; cloud-init/Ignition documents are translated via
+// Normalize first; Normalize's own CloudConfig pass-through can't be
+// strict-decoded the same way, since it's already been rendered down
+// from a foreign schema.
+func ReadConfig() (CloudConfig, error) {
+	var cfg CloudConfig
+
+	raw, err := ioutil.ReadFile(LocalConfig)
+	if os.IsNotExist(err) {
+		raw, err = ioutil.ReadFile(SystemConfig)
+	}
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return cfg, err
+	}
+
+	if IsNativeFormat(raw) {
+		return UnmarshalStrict(raw)
+	}
+
+	normalized, err := Normalize(raw)
+	if err != nil {
+		return cfg, err
+	}
+
+	if err := yaml.Unmarshal(normalized, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// JSONSchema renders a JSON Schema (draft-07) for CloudConfig by walking
+// its struct tags with reflect, so editor tooling can offer completion
+// and validation for cloud-config files without this package having to
+// maintain a second, hand-written copy of the shape.
+func JSONSchema() ([]byte, error) {
+	schema := map[string]interface{}{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"title":   "CloudConfig",
+	}
+	for k, v := range schemaFor(reflect.TypeOf(CloudConfig{})) {
+		schema[k] = v
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaFor returns the "type"/"properties"/... schema fragment for t.
+func schemaFor(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if _, ok := reflect.PtrTo(t).MethodByName("UnmarshalYAML"); ok {
+		// A type with its own UnmarshalYAML - Command, whose real shape
+		// on the wire is a string or a list of strings - can't be
+		// reflected field-by-field; its Go fields are just where the
+		// decoded value lands.
+		return map[string]interface{}{
+			"oneOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, ok := yamlFieldName(field)
+			if !ok {
+				continue
+			}
+			properties[name] = schemaFor(field.Type)
+		}
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": properties,
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": schemaFor(t.Elem()),
+		}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": schemaFor(t.Elem()),
+		}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Interface:
+		return map[string]interface{}{}
+	default:
+		return map[string]interface{}{"type": "string"}
+	}
+}
+
+// yamlFieldName returns the field's yaml tag name, and false if the
+// field has no tag (Command, which has its own UnmarshalYAML) or is
+// explicitly skipped with "-".
+func yamlFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "" {
+		return "", false
+	}
+	name := strings.Split(tag, ",")[0]
+	if name == "-" || name == "" {
+		return "", false
+	}
+	return name, true
+}
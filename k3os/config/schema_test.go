@@ -0,0 +1,36 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONSchemaRuncmdAcceptsStringOrArray(t *testing.T) {
+	raw, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	props, ok := schema["properties"].(map[string]interface{})
+	if !ok {
+		t.Fatal("schema has no top-level properties")
+	}
+	runcmd, ok := props["runcmd"].(map[string]interface{})
+	if !ok {
+		t.Fatal("schema has no runcmd property")
+	}
+	items, ok := runcmd["items"].(map[string]interface{})
+	if !ok {
+		t.Fatal("runcmd is not an array schema")
+	}
+
+	oneOf, ok := items["oneOf"].([]interface{})
+	if !ok || len(oneOf) != 2 {
+		t.Fatalf("runcmd items should accept a string or an array of strings, got %v", items)
+	}
+}
@@ -0,0 +1,73 @@
+package config
+
+import "gopkg.in/yaml.v2"
+
+// ConfigSource recognizes one on-disk cloud-config format and
+// translates it into the native CloudConfig k3os understands.
+type ConfigSource interface {
+	// Detect reports whether data looks like this source's format.
+	Detect(data []byte) bool
+	// ToCloudConfig parses data and renders it as a CloudConfig.
+	ToCloudConfig(data []byte) (CloudConfig, error)
+}
+
+// Sources is tried, in order, against any config k3os is handed from
+// outside - an installer --cloud-config file, a k3os.install.config_url
+// download, and so on - so that configs written for other distros can
+// be brought in unchanged. K3OSSource is last and always matches, so a
+// document none of the others recognise falls through to it.
+var Sources = []ConfigSource{
+	CloudInitSource{},
+	IgnitionSource{},
+	K3OSSource{},
+}
+
+// Normalize detects which format data is written in and renders it back
+// out as native k3os YAML, so every downstream consumer - the install
+// scripts, ReadConfig, ccapply - only ever has to deal with one format.
+func Normalize(data []byte) ([]byte, error) {
+	for _, src := range Sources {
+		if !src.Detect(data) {
+			continue
+		}
+		cfg, err := src.ToCloudConfig(data)
+		if err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(&cfg)
+	}
+	return data, nil
+}
+
+// IsNativeFormat reports whether data is k3os's own cloud-config
+// format, as opposed to a foreign cloud-init/Ignition document
+// Normalize would translate. Callers that want to strict-decode a
+// config to catch a typo'd key (see UnmarshalStrict) need this first:
+// cloud-init/Ignition documents are full of field names k3os's own
+// CloudConfig doesn't have, so strict-decoding one of those directly
+// would misreport every one of its real fields as a typo.
+func IsNativeFormat(data []byte) bool {
+	for _, src := range Sources {
+		if _, ok := src.(K3OSSource); ok {
+			return true
+		}
+		if src.Detect(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// K3OSSource is the native k3os cloud-config YAML format. It matches
+// everything, so it must be tried last.
+type K3OSSource struct{}
+
+func (K3OSSource) Detect([]byte) bool { return true }
+
+func (K3OSSource) ToCloudConfig(data []byte) (CloudConfig, error) {
+	var cfg CloudConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// cloudInitHeader is the magic comment cloud-init requires at the top
+// of a user-data document.
+const cloudInitHeader = "#cloud-config"
+
+// CloudInitSource reads a subset of the cloud-init user-data format:
+// enough to carry over an SSH-keyed login, a hostname, a password and
+// first-boot commands from a config written for another cloud-init
+// consumer (Flatcar, CoreOS, Ubuntu, ...). Of write_files, only an
+// /etc/hostname entry is used, as the hostname; other files are not
+// carried over.
+type CloudInitSource struct{}
+
+func (CloudInitSource) Detect(data []byte) bool {
+	return strings.HasPrefix(strings.TrimSpace(string(data)), cloudInitHeader)
+}
+
+type cloudInitUser struct {
+	Name              string   `yaml:"name,omitempty"`
+	SSHAuthorizedKeys []string `yaml:"ssh_authorized_keys,omitempty"`
+}
+
+type cloudInitWriteFile struct {
+	Path    string `yaml:"path,omitempty"`
+	Content string `yaml:"content,omitempty"`
+}
+
+type cloudInitDoc struct {
+	Hostname          string               `yaml:"hostname,omitempty"`
+	Users             []cloudInitUser      `yaml:"users,omitempty"`
+	SSHAuthorizedKeys []string             `yaml:"ssh_authorized_keys,omitempty"`
+	WriteFiles        []cloudInitWriteFile `yaml:"write_files,omitempty"`
+	Runcmd            []Command            `yaml:"runcmd,omitempty"`
+	Chpasswd          struct {
+		List string `yaml:"list,omitempty"`
+	} `yaml:"chpasswd,omitempty"`
+}
+
+func (CloudInitSource) ToCloudConfig(data []byte) (CloudConfig, error) {
+	var cfg CloudConfig
+
+	trimmed := bytes.TrimPrefix(bytes.TrimSpace(data), []byte(cloudInitHeader))
+
+	var doc cloudInitDoc
+	if err := yaml.Unmarshal(trimmed, &doc); err != nil {
+		return cfg, err
+	}
+
+	cfg.Hostname = doc.Hostname
+	cfg.Runcmd = doc.Runcmd
+
+	keys := append([]string{}, doc.SSHAuthorizedKeys...)
+	for _, user := range doc.Users {
+		keys = append(keys, user.SSHAuthorizedKeys...)
+	}
+	cfg.K3OS.SSH.AuthorizedKeys = keys
+
+	for _, line := range strings.Split(doc.Chpasswd.List, "\n") {
+		if parts := strings.SplitN(strings.TrimSpace(line), ":", 2); len(parts) == 2 {
+			cfg.K3OS.Password = parts[1]
+			break
+		}
+	}
+
+	for _, f := range doc.WriteFiles {
+		if f.Path == "/etc/hostname" && cfg.Hostname == "" {
+			cfg.Hostname = strings.TrimSpace(f.Content)
+		}
+	}
+
+	return cfg, nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// IgnitionSource reads the parts of an Ignition v3 config (as produced
+// for Flatcar/RHCOS) that have a direct k3os equivalent: users and
+// their SSH keys, a hostname dropped via storage.files, and systemd
+// units to enable.
+type IgnitionSource struct{}
+
+type ignitionDoc struct {
+	Ignition struct {
+		Version string `json:"version"`
+	} `json:"ignition"`
+	Passwd struct {
+		Users []struct {
+			Name              string   `json:"name"`
+			SSHAuthorizedKeys []string `json:"sshAuthorizedKeys"`
+		} `json:"users"`
+	} `json:"passwd"`
+	Storage struct {
+		Files []struct {
+			Path     string `json:"path"`
+			Contents struct {
+				Source string `json:"source"`
+			} `json:"contents"`
+		} `json:"files"`
+	} `json:"storage"`
+	Systemd struct {
+		Units []struct {
+			Name    string `json:"name"`
+			Enabled bool   `json:"enabled"`
+		} `json:"units"`
+	} `json:"systemd"`
+}
+
+func (IgnitionSource) Detect(data []byte) bool {
+	var doc ignitionDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return false
+	}
+	return doc.Ignition.Version != ""
+}
+
+func (IgnitionSource) ToCloudConfig(data []byte) (CloudConfig, error) {
+	var cfg CloudConfig
+
+	var doc ignitionDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return cfg, err
+	}
+
+	var keys []string
+	for _, user := range doc.Passwd.Users {
+		keys = append(keys, user.SSHAuthorizedKeys...)
+	}
+	cfg.K3OS.SSH.AuthorizedKeys = keys
+
+	for _, f := range doc.Storage.Files {
+		if f.Path != "/etc/hostname" {
+			continue
+		}
+		content, err := decodeIgnitionDataURL(f.Contents.Source)
+		if err != nil {
+			return cfg, fmt.Errorf("decoding %s: %v", f.Path, err)
+		}
+		cfg.Hostname = strings.TrimSpace(content)
+	}
+
+	for _, unit := range doc.Systemd.Units {
+		if unit.Enabled {
+			cfg.Runcmd = append(cfg.Runcmd, Command{String: "systemctl enable --now " + unit.Name})
+		}
+	}
+
+	return cfg, nil
+}
+
+// decodeIgnitionDataURL decodes the "data:" URLs Ignition uses to embed
+// file contents, e.g. "data:,hello" or "data:;base64,aGVsbG8=".
+func decodeIgnitionDataURL(source string) (string, error) {
+	const prefix = "data:"
+	if !strings.HasPrefix(source, prefix) {
+		return "", fmt.Errorf("unsupported contents source: %s", source)
+	}
+	rest := strings.TrimPrefix(source, prefix)
+
+	comma := strings.Index(rest, ",")
+	if comma == -1 {
+		return "", fmt.Errorf("malformed data URL: %s", source)
+	}
+	meta, payload := rest[:comma], rest[comma+1:]
+
+	if strings.Contains(meta, "base64") {
+		decoded, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			return "", err
+		}
+		return string(decoded), nil
+	}
+
+	return url.QueryUnescape(payload)
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		hostname string
+		keys     []string
+	}{
+		{
+			name:     "native k3os yaml",
+			data:     "hostname: box1\nk3os:\n  ssh:\n    authorized_keys:\n    - ssh-ed25519 AAAA native\n",
+			hostname: "box1",
+			keys:     []string{"ssh-ed25519 AAAA native"},
+		},
+		{
+			name: "cloud-init user-data",
+			data: "#cloud-config\nhostname: box2\nusers:\n- name: rancher\n  ssh_authorized_keys:\n  - ssh-ed25519 AAAA cloudinit\n",
+			hostname: "box2",
+			keys:     []string{"ssh-ed25519 AAAA cloudinit"},
+		},
+		{
+			name: "ignition json",
+			data: `{"ignition":{"version":"3.3.0"},"passwd":{"users":[{"name":"rancher","sshAuthorizedKeys":["ssh-ed25519 AAAA ignition"]}]}}`,
+			keys: []string{"ssh-ed25519 AAAA ignition"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			normalized, err := Normalize([]byte(tc.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var cfg CloudConfig
+			if err := yaml.Unmarshal(normalized, &cfg); err != nil {
+				t.Fatalf("Normalize produced invalid k3os yaml: %v\n%s", err, normalized)
+			}
+
+			if tc.hostname != "" && cfg.Hostname != tc.hostname {
+				t.Errorf("hostname = %q, want %q", cfg.Hostname, tc.hostname)
+			}
+			if len(cfg.K3OS.SSH.AuthorizedKeys) != len(tc.keys) || cfg.K3OS.SSH.AuthorizedKeys[0] != tc.keys[0] {
+				t.Errorf("authorized keys = %v, want %v", cfg.K3OS.SSH.AuthorizedKeys, tc.keys)
+			}
+		})
+	}
+}
@@ -7,6 +7,12 @@ const (
 	CloudConfigDir  = "/var/lib/rancher/k3os/conf/cloud-config.d"
 	CloudConfigFile = "/var/lib/rancher/k3os/conf/cloud-config.yml"
 	K3OSPasswordKey = "k3os.password"
+
+	SystemConfig = "/k3os/system/config.yaml"
+	LocalConfig  = "/var/lib/rancher/k3os/config.yaml"
+
+	ServerRole = "server"
+	AgentRole  = "agent"
 )
 
 var (
@@ -48,13 +54,55 @@ type InterfaceConfig struct {
 }
 
 type K3OSConfig struct {
-	Defaults Defaults          `yaml:"defaults,omitempty"`
-	Modules  []string          `yaml:"modules,omitempty"`
-	Network  NetworkConfig     `yaml:"network,omitempty"`
-	SSH      SSHConfig         `yaml:"ssh,omitempty"`
-	Sysctl   map[string]string `yaml:"sysctl,omitempty"`
-	Upgrade  UpgradeConfig     `yaml:"upgrade,omitempty"`
-	Password string            `yaml:"password,omitempty"`
+	Defaults  Defaults          `yaml:"defaults,omitempty"`
+	Modules   []string          `yaml:"modules,omitempty"`
+	Network   NetworkConfig     `yaml:"network,omitempty"`
+	SSH       SSHConfig         `yaml:"ssh,omitempty"`
+	Sysctl    map[string]string `yaml:"sysctl,omitempty"`
+	Upgrade   UpgradeConfig     `yaml:"upgrade,omitempty"`
+	Password  string            `yaml:"password,omitempty"`
+	Mode      string            `yaml:"mode,omitempty"`
+	Install   Install           `yaml:"install,omitempty"`
+	Role      string            `yaml:"role,omitempty"`
+	Token     string            `yaml:"token,omitempty"`
+	ServerURL string            `yaml:"serverUrl,omitempty"`
+}
+
+// Install holds the answers needed to lay k3os down on disk, as
+// produced either by the interactive wizard (pkg/cliinstall.Ask) or by
+// a cloud-config supplied up front.
+type Install struct {
+	Silent      bool   `yaml:"silent,omitempty"`
+	ConfigURL   string `yaml:"configUrl,omitempty"`
+	PowerOff    bool   `yaml:"powerOff,omitempty"`
+	NoFormat    bool   `yaml:"noFormat,omitempty"`
+	Device      string `yaml:"device,omitempty"`
+	InstallType string `yaml:"installType,omitempty"`
+
+	// Partitions, when set, replaces Device/InstallType's single
+	// gptmbr/mbr/efi script with a declarative layout that
+	// pkg/partition resolves into sgdisk/mkfs/cryptsetup/mdadm calls.
+	Partitions []PartitionSpec `yaml:"partitions,omitempty"`
+}
+
+// PartitionSpec describes one partition of a declarative Install.Partitions
+// layout.
+type PartitionSpec struct {
+	Label      string    `yaml:"label,omitempty"`
+	FSType     string    `yaml:"fsType,omitempty"`
+	SizeMiB    int       `yaml:"sizeMib,omitempty"` // 0 means "rest of the disk"
+	MountPoint string    `yaml:"mountPoint,omitempty"`
+	Encrypted  bool      `yaml:"encrypted,omitempty"`
+	Passphrase string    `yaml:"passphrase,omitempty"`
+	TPM2       bool      `yaml:"tpm2,omitempty"`
+	RAID       *RaidSpec `yaml:"raid,omitempty"`
+}
+
+// RaidSpec mirrors this partition onto additional disks with mdadm.
+type RaidSpec struct {
+	Level   int      `yaml:"level,omitempty"`
+	Name    string   `yaml:"name,omitempty"` // assembled at /dev/md/<name>
+	Members []string `yaml:"members,omitempty"`
 }
 
 type ProxyConfig struct {
@@ -80,6 +128,12 @@ type NetworkConfig struct {
 	DNS        DNSConfig                  `yaml:"dns,omitempty"`
 	Interfaces map[string]InterfaceConfig `yaml:"interfaces,omitempty"`
 	Proxy      ProxyConfig                `yaml:"proxy,omitempty"`
+	Wifi       []WifiConfig               `yaml:"wifi,omitempty"`
+}
+
+type WifiConfig struct {
+	Name       string `yaml:"name,omitempty"`
+	Passphrase string `yaml:"passphrase,omitempty"`
 }
 
 func (c *Command) UnmarshalYAML(unmarshal func(interface{}) error) error {
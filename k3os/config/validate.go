@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+
+	yamlv2 "gopkg.in/yaml.v2"
+)
+
+// ValidationError names the field a CloudConfig problem was found in,
+// so e.g. an out-of-range k3os.ssh.port reports exactly where it went
+// wrong. Unknown keys are reported by UnmarshalStrict, not here.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+var hostnameRE = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+
+var upgradePolicies = map[string]bool{
+	"":       true,
+	"stable": true,
+	"latest": true,
+}
+
+// UnmarshalStrict parses data the same shape ReadConfig expects, but
+// rejects unknown fields instead of silently dropping them.
+func UnmarshalStrict(data []byte) (CloudConfig, error) {
+	var cfg CloudConfig
+	if err := yamlv2.UnmarshalStrict(data, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the field-level constraints UnmarshalStrict can't:
+// a well-formed hostname, CIDR network addresses, an in-range SSH
+// port, and a recognised upgrade policy.
+func Validate(cfg CloudConfig) []ValidationError {
+	var errs []ValidationError
+
+	if cfg.Hostname != "" && !hostnameRE.MatchString(cfg.Hostname) {
+		errs = append(errs, ValidationError{"hostname", fmt.Sprintf("%q is not a valid hostname", cfg.Hostname)})
+	}
+
+	for name, iface := range cfg.K3OS.Network.Interfaces {
+		for _, addr := range iface.Addresses {
+			if _, _, err := net.ParseCIDR(addr); err != nil {
+				errs = append(errs, ValidationError{
+					Field:   fmt.Sprintf("k3os.network.interfaces.%s.addresses", name),
+					Message: fmt.Sprintf("%q is not a valid CIDR address", addr),
+				})
+			}
+		}
+	}
+
+	if port := cfg.K3OS.SSH.Port; port != 0 && (port < 1 || port > 65535) {
+		errs = append(errs, ValidationError{"k3os.ssh.port", fmt.Sprintf("%d is not a valid port", port)})
+	}
+
+	if !upgradePolicies[cfg.K3OS.Upgrade.Policy] {
+		errs = append(errs, ValidationError{
+			Field:   "k3os.upgrade.policy",
+			Message: fmt.Sprintf("%q must be one of stable, latest", cfg.K3OS.Upgrade.Policy),
+		})
+	}
+
+	return errs
+}
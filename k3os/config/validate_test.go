@@ -0,0 +1,37 @@
+package config
+
+import "testing"
+
+func TestIsNativeFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		native bool
+	}{
+		{"native k3os yaml", "hostname: box1\nk3os:\n  ssh:\n    authorized_keys: []\n", true},
+		{"cloud-init user-data", "#cloud-config\nhostname: box2\n", false},
+		{"ignition json", `{"ignition":{"version":"3.3.0"}}`, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsNativeFormat([]byte(tc.data)); got != tc.native {
+				t.Errorf("IsNativeFormat(%q) = %v, want %v", tc.data, got, tc.native)
+			}
+		})
+	}
+}
+
+func TestUnmarshalStrictRejectsTypoedKey(t *testing.T) {
+	_, err := UnmarshalStrict([]byte("k3os:\n  ssh:\n    authorised_keys:\n    - ssh-ed25519 AAAA\n"))
+	if err == nil {
+		t.Fatal("expected an error for the typo'd authorised_keys, got nil")
+	}
+}
+
+func TestUnmarshalStrictAcceptsValidConfig(t *testing.T) {
+	_, err := UnmarshalStrict([]byte("hostname: box1\nk3os:\n  ssh:\n    authorized_keys:\n    - ssh-ed25519 AAAA\n"))
+	if err != nil {
+		t.Fatalf("UnmarshalStrict rejected a valid config: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package cliinstall
+
+import (
+	"fmt"
+
+	"github.com/rancher/k3os/config"
+	"github.com/rancher/k3os/pkg/cliinstall/asset"
+	"github.com/rancher/k3os/pkg/util"
+)
+
+// wizard is the ordered set of assets Ask walks the user through. Order
+// matters: it's also the order the user steps back and forth through.
+var wizard = []Asset{
+	diskAsset{},
+	installTypeAsset{},
+	hostnameAsset{},
+	sshKeyAsset{},
+	k3sRoleAsset{},
+	tokenAsset{},
+	upgradeChannelAsset{},
+	wifiAsset{},
+}
+
+// Ask walks the user through the install wizard one asset at a time,
+// staging each answer in asset.Store so that a failed or interrupted
+// install can resume from asset.StateFile rather than starting over.
+// Typing "b" at any prompt steps back to the previous asset. It returns
+// true when the user chooses to install now.
+func Ask(cfg *config.CloudConfig) (bool, error) {
+	if !util.Yes("Install k3OS to disk") {
+		return false, nil
+	}
+
+	store, err := asset.NewStore()
+	if err != nil {
+		return false, err
+	}
+
+	answers := map[string]interface{}{}
+	for i := 0; i < len(wizard); {
+		a := wizard[i]
+		val, err := store.Fetch(a)
+		if err == errBack {
+			if i == 0 {
+				continue
+			}
+			forgotten, err := store.Forget(wizard[i-1], wizard)
+			if err != nil {
+				return false, err
+			}
+			for _, name := range forgotten {
+				delete(answers, name)
+			}
+			i--
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		answers[a.Name()] = val
+		i++
+	}
+
+	return true, applyAnswers(cfg, answers)
+}
+
+// applyAnswers renders the wizard's answers into the validated
+// config.CloudConfig that runInstall hands off to
+// /usr/libexec/k3os/install.
+func applyAnswers(cfg *config.CloudConfig, answers map[string]interface{}) error {
+	cfg.Hostname, _ = answers["hostname"].(string)
+	cfg.K3OS.Install.Device, _ = answers["disk"].(string)
+	cfg.K3OS.Install.InstallType, _ = answers["installType"].(string)
+	cfg.K3OS.Role, _ = answers["role"].(string)
+	cfg.K3OS.Token, _ = answers["token"].(string)
+	cfg.K3OS.Upgrade.Policy, _ = answers["upgradeChannel"].(string)
+
+	if keys, ok := answers["sshKeys"].([]string); ok {
+		cfg.K3OS.SSH.AuthorizedKeys = keys
+	}
+
+	if wifi, ok := answers["wifi"].(config.WifiConfig); ok {
+		cfg.K3OS.Network.Wifi = []config.WifiConfig{wifi}
+	}
+
+	if cfg.K3OS.Install.Device == "" {
+		return fmt.Errorf("no install device chosen")
+	}
+	return nil
+}
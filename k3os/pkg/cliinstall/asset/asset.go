@@ -0,0 +1,31 @@
+package asset
+
+// Asset is a single answer the install wizard needs in order to build
+// the final config.CloudConfig, modeled after the asset-generation
+// approach used by openshift-installer: each asset knows what it
+// depends on and how to produce its own value once those dependencies
+// are satisfied.
+type Asset interface {
+	// Name uniquely identifies the asset so its value can be cached
+	// and looked back up by dependents.
+	Name() string
+
+	// Dependencies lists the assets that must be generated before this
+	// one can be.
+	Dependencies() []Asset
+
+	// Generate produces the asset's value. parents is keyed by the
+	// Name() of each entry returned from Dependencies().
+	Generate(parents map[string]interface{}) (interface{}, error)
+}
+
+// CachedUnmarshaler is implemented by assets whose value is a concrete
+// type - a slice or struct, say - rather than a string, number or bool.
+// Those round-trip through Store's plain json.Unmarshal into
+// map[string]interface{} as []interface{}/map[string]interface{}
+// instead of their original type, so the caller's type assertion on a
+// resumed value fails silently. Store uses UnmarshalCached instead,
+// when an asset implements it, to restore the real type.
+type CachedUnmarshaler interface {
+	UnmarshalCached(data []byte) (interface{}, error)
+}
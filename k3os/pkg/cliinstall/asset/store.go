@@ -0,0 +1,164 @@
+package asset
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+)
+
+// StateFile caches answers already given to the wizard so that a
+// failed or interrupted install can resume instead of re-asking every
+// question.
+const StateFile = "/tmp/install-state.json"
+
+// Store resolves an Asset and all of its transitive Dependencies,
+// persisting each generated value to StateFile as it goes.
+type Store struct {
+	// raw holds answers loaded from a previous run, still as the raw
+	// JSON they were saved as. Fetch decodes each into state, through
+	// the asset's CachedUnmarshaler when it has one, the first time
+	// it's asked for - so an asset that was never resumed this run
+	// doesn't need to be re-marshaled on save.
+	raw   map[string]json.RawMessage
+	state map[string]interface{}
+}
+
+// NewStore loads any answers left behind by a previous run of the
+// wizard, if present.
+func NewStore() (*Store, error) {
+	s := &Store{raw: map[string]json.RawMessage{}, state: map[string]interface{}{}}
+	bytes, err := ioutil.ReadFile(StateFile)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bytes, &s.raw); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Fetch returns the cached value for a, generating it - and, first,
+// every dependency it transitively needs - if it hasn't been answered
+// yet.
+func (s *Store) Fetch(a Asset) (interface{}, error) {
+	if val, ok := s.state[a.Name()]; ok {
+		return val, nil
+	}
+
+	if data, ok := s.raw[a.Name()]; ok {
+		val, err := decodeCached(a, data)
+		if err != nil {
+			return nil, err
+		}
+		s.state[a.Name()] = val
+		delete(s.raw, a.Name())
+		return val, nil
+	}
+
+	parents := map[string]interface{}{}
+	for _, dep := range a.Dependencies() {
+		val, err := s.Fetch(dep)
+		if err != nil {
+			return nil, err
+		}
+		parents[dep.Name()] = val
+	}
+
+	val, err := a.Generate(parents)
+	if err != nil {
+		return nil, err
+	}
+
+	return val, s.Put(a.Name(), val)
+}
+
+// decodeCached restores data - as loaded verbatim from StateFile - to
+// the type a.Generate would have returned, using a's CachedUnmarshaler
+// if it has one.
+func decodeCached(a Asset, data json.RawMessage) (interface{}, error) {
+	if u, ok := a.(CachedUnmarshaler); ok {
+		return u.UnmarshalCached(data)
+	}
+	var val interface{}
+	err := json.Unmarshal(data, &val)
+	return val, err
+}
+
+// Put overwrites the cached value for name, used when the user
+// navigates back through the wizard to change an earlier answer.
+func (s *Store) Put(name string, val interface{}) error {
+	s.state[name] = val
+	delete(s.raw, name)
+	return s.save()
+}
+
+// Forget discards the cached value for a, and for every asset in all
+// that transitively depends on it, so the next Fetch regenerates each
+// of them instead of replaying an answer that assumed a's old value.
+// all should be the full wizard, not just the assets already answered:
+// an asset downstream of a that hasn't been reached yet obviously has
+// nothing stale to discard, but it costs nothing to check. Returns the
+// names actually discarded.
+func (s *Store) Forget(a Asset, all []Asset) ([]string, error) {
+	stale := map[string]bool{a.Name(): true}
+	for changed := true; changed; {
+		changed = false
+		for _, candidate := range all {
+			if stale[candidate.Name()] {
+				continue
+			}
+			for _, dep := range candidate.Dependencies() {
+				if stale[dep.Name()] {
+					stale[candidate.Name()] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	var names []string
+	for name := range stale {
+		if _, ok := s.state[name]; !ok {
+			if _, ok := s.raw[name]; !ok {
+				continue
+			}
+		}
+		delete(s.state, name)
+		delete(s.raw, name)
+		names = append(names, name)
+	}
+	return names, s.save()
+}
+
+// Reset discards all cached answers so the wizard starts from scratch
+// on its next run.
+func (s *Store) Reset() error {
+	s.raw = map[string]json.RawMessage{}
+	s.state = map[string]interface{}{}
+	if err := os.Remove(StateFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// save persists every answered asset - already-decoded ones in state,
+// plus any still-raw ones this run never touched - back to StateFile.
+func (s *Store) save() error {
+	out := map[string]interface{}{}
+	for name, data := range s.raw {
+		out[name] = data
+	}
+	for name, val := range s.state {
+		out[name] = val
+	}
+
+	bytes, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(StateFile, bytes, 0600)
+}
@@ -0,0 +1,64 @@
+package asset
+
+import "testing"
+
+// stubAsset is a minimal Asset whose Generate just counts how many
+// times it ran, so tests can tell a stale cache was actually
+// regenerated rather than replayed.
+type stubAsset struct {
+	name  string
+	deps  []Asset
+	value interface{}
+	calls *int
+}
+
+func (a stubAsset) Name() string          { return a.name }
+func (a stubAsset) Dependencies() []Asset { return a.deps }
+func (a stubAsset) Generate(map[string]interface{}) (interface{}, error) {
+	*a.calls++
+	return a.value, nil
+}
+
+func TestForgetCascadesToDependents(t *testing.T) {
+	var roleCalls, tokenCalls, hostnameCalls int
+	role := stubAsset{name: "role", value: "server", calls: &roleCalls}
+	token := stubAsset{name: "token", deps: []Asset{role}, value: "", calls: &tokenCalls}
+	hostname := stubAsset{name: "hostname", value: "box1", calls: &hostnameCalls}
+	wizard := []Asset{role, token, hostname}
+
+	s, err := NewStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Reset()
+
+	for _, a := range wizard {
+		if _, err := s.Fetch(a); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if roleCalls != 1 || tokenCalls != 1 || hostnameCalls != 1 {
+		t.Fatalf("expected each asset generated once, got role=%d token=%d hostname=%d", roleCalls, tokenCalls, hostnameCalls)
+	}
+
+	if _, err := s.Forget(role, wizard); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Fetch(hostname); err != nil {
+		t.Fatal(err)
+	}
+	if hostnameCalls != 1 {
+		t.Fatalf("hostname does not depend on role, should not be regenerated, got %d calls", hostnameCalls)
+	}
+
+	if _, err := s.Fetch(token); err != nil {
+		t.Fatal(err)
+	}
+	if tokenCalls != 2 {
+		t.Fatalf("token depends on role, forgetting role should force it to regenerate, got %d calls", tokenCalls)
+	}
+}
@@ -1,12 +1,14 @@
 package cliinstall
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
+	"strings"
 
 	"github.com/ghodss/yaml"
-	"github.com/rancher/k3os/pkg/config"
+	"github.com/rancher/k3os/config"
 )
 
 func Run() error {
@@ -20,6 +22,14 @@ func Run() error {
 		return err
 	}
 
+	if errs := config.Validate(cfg); len(errs) > 0 {
+		msgs := make([]string, len(errs))
+		for i, e := range errs {
+			msgs[i] = e.Error()
+		}
+		return fmt.Errorf("invalid cloud-config:\n%s", strings.Join(msgs, "\n"))
+	}
+
 	if isInstall {
 		return runInstall(cfg)
 	}
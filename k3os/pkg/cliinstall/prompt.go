@@ -0,0 +1,87 @@
+package cliinstall
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// errBack is returned by the prompt helpers when the user types "b" to
+// step back to the previous asset in the wizard.
+var errBack = fmt.Errorf("back")
+
+var stdin = bufio.NewReader(os.Stdin)
+
+func promptString(question, def string) (string, error) {
+	if def == "" {
+		fmt.Printf("%s ('b' to go back): ", question)
+	} else {
+		fmt.Printf("%s ('b' to go back) [%s]: ", question, def)
+	}
+
+	line, err := readLine()
+	if err != nil {
+		return "", err
+	}
+
+	if line == "b" {
+		return "", errBack
+	}
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+func promptChoice(question string, choices []string, def string) (string, error) {
+	fmt.Printf("%s (%s) ('b' to go back) [%s]: ", question, strings.Join(choices, "/"), def)
+
+	line, err := readLine()
+	if err != nil {
+		return "", err
+	}
+
+	if line == "b" {
+		return "", errBack
+	}
+	if line == "" {
+		line = def
+	}
+	for _, choice := range choices {
+		if line == choice {
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not one of %s", line, strings.Join(choices, ", "))
+}
+
+func promptIndex(question string, choices []string) (int, error) {
+	for i, choice := range choices {
+		fmt.Printf("  %d) %s\n", i, choice)
+	}
+	fmt.Printf("%s ('b' to go back): ", question)
+
+	line, err := readLine()
+	if err != nil {
+		return 0, err
+	}
+
+	if line == "b" {
+		return 0, errBack
+	}
+	i, err := strconv.Atoi(line)
+	if err != nil || i < 0 || i >= len(choices) {
+		return 0, fmt.Errorf("%q is not a valid choice", line)
+	}
+	return i, nil
+}
+
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
@@ -0,0 +1,216 @@
+package cliinstall
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"strings"
+
+	"github.com/rancher/k3os/config"
+	"github.com/rancher/k3os/pkg/cliinstall/asset"
+	"github.com/rancher/k3os/pkg/util"
+)
+
+// Asset is a convenience alias so the step types below don't need to
+// qualify every Dependencies() return with the asset package name.
+type Asset = asset.Asset
+
+// diskAsset asks which block device k3os should be installed to. It
+// shells out to lsblk the same way the rest of the installer shells
+// out to the k3os-install-* scripts.
+type diskAsset struct{}
+
+func (diskAsset) Name() string          { return "disk" }
+func (diskAsset) Dependencies() []Asset { return nil }
+func (d diskAsset) Generate(map[string]interface{}) (interface{}, error) {
+	out, err := exec.Command("lsblk", "-d", "-n", "-o", "NAME,SIZE,TYPE").Output()
+	if err != nil {
+		return nil, fmt.Errorf("listing disks: %v", err)
+	}
+
+	var disks []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		disks = append(disks, "/dev/"+fields[0]+" ("+strings.Join(fields[1:], " ")+")")
+	}
+	if len(disks) == 0 {
+		return nil, fmt.Errorf("no disks found")
+	}
+
+	i, err := promptIndex("Which disk would you like to install to?", disks)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(disks[i])[0], nil
+}
+
+// installTypeAsset asks how the bootloader should be laid down.
+type installTypeAsset struct{}
+
+func (installTypeAsset) Name() string         { return "installType" }
+func (installTypeAsset) Dependencies() []Asset { return nil }
+func (installTypeAsset) Generate(map[string]interface{}) (interface{}, error) {
+	return promptChoice("Install type", []string{"gptmbr", "mbr", "efi"}, "gptmbr")
+}
+
+// hostnameAsset asks for the hostname the installed system should use.
+type hostnameAsset struct{}
+
+func (hostnameAsset) Name() string         { return "hostname" }
+func (hostnameAsset) Dependencies() []Asset { return nil }
+func (hostnameAsset) Generate(map[string]interface{}) (interface{}, error) {
+	return promptString("Hostname", "k3os")
+}
+
+// sshKeyAsset asks where to import authorized SSH keys from: a GitHub
+// username, an arbitrary URL serving a keys file, or a local file.
+type sshKeyAsset struct{}
+
+func (sshKeyAsset) Name() string         { return "sshKeys" }
+func (sshKeyAsset) Dependencies() []Asset { return nil }
+func (sshKeyAsset) Generate(map[string]interface{}) (interface{}, error) {
+	source, err := promptChoice("Import SSH authorized keys from", []string{"github", "url", "file", "none"}, "github")
+	if err != nil {
+		return nil, err
+	}
+
+	switch source {
+	case "none":
+		return nil, nil
+	case "github":
+		user, err := promptString("GitHub username", "")
+		if err != nil {
+			return nil, err
+		}
+		return fetchKeys(fmt.Sprintf("https://github.com/%s.keys", user))
+	case "url":
+		url, err := promptString("Keys URL", "")
+		if err != nil {
+			return nil, err
+		}
+		return fetchKeys(url)
+	default: // file
+		path, err := promptString("Path to keys file", "")
+		if err != nil {
+			return nil, err
+		}
+		bytes, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return splitKeys(string(bytes)), nil
+	}
+}
+
+// UnmarshalCached restores a resumed answer as the []string Generate
+// returns, rather than the []interface{} a plain json.Unmarshal into
+// interface{} would produce.
+func (sshKeyAsset) UnmarshalCached(data []byte) (interface{}, error) {
+	if string(data) == "null" {
+		return nil, nil
+	}
+	var keys []string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func fetchKeys(url string) ([]string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	bytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return splitKeys(string(bytes)), nil
+}
+
+func splitKeys(raw string) []string {
+	var keys []string
+	for _, line := range strings.Split(raw, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			keys = append(keys, line)
+		}
+	}
+	return keys
+}
+
+// k3sRoleAsset asks whether this node joins the cluster as a server or
+// an agent.
+type k3sRoleAsset struct{}
+
+func (k3sRoleAsset) Name() string         { return "role" }
+func (k3sRoleAsset) Dependencies() []Asset { return nil }
+func (k3sRoleAsset) Generate(map[string]interface{}) (interface{}, error) {
+	return promptChoice("k3s role", []string{config.ServerRole, config.AgentRole}, config.ServerRole)
+}
+
+// tokenAsset asks for the cluster token. It's required to join as an
+// agent and optional (k3s will generate one) for a server.
+type tokenAsset struct{}
+
+func (tokenAsset) Name() string          { return "token" }
+func (tokenAsset) Dependencies() []Asset { return []Asset{k3sRoleAsset{}} }
+func (tokenAsset) Generate(parents map[string]interface{}) (interface{}, error) {
+	role := parents[k3sRoleAsset{}.Name()].(string)
+	if role == config.AgentRole {
+		return promptString("Cluster token", "")
+	}
+	return promptString("Cluster token (blank to generate one)", "")
+}
+
+// upgradeChannelAsset asks which upgrade channel the node should track.
+type upgradeChannelAsset struct{}
+
+func (upgradeChannelAsset) Name() string         { return "upgradeChannel" }
+func (upgradeChannelAsset) Dependencies() []Asset { return nil }
+func (upgradeChannelAsset) Generate(map[string]interface{}) (interface{}, error) {
+	return promptChoice("Upgrade channel", []string{"stable", "latest"}, "stable")
+}
+
+// wifiAsset optionally configures a wifi network to bring networking up
+// before the cluster join is attempted.
+type wifiAsset struct{}
+
+func (wifiAsset) Name() string         { return "wifi" }
+func (wifiAsset) Dependencies() []Asset { return nil }
+func (wifiAsset) Generate(map[string]interface{}) (interface{}, error) {
+	if !util.Yes("Configure wifi") {
+		return nil, nil
+	}
+
+	ssid, err := promptString("Wifi SSID", "")
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := promptString("Wifi passphrase", "")
+	if err != nil {
+		return nil, err
+	}
+	return config.WifiConfig{Name: ssid, Passphrase: passphrase}, nil
+}
+
+// UnmarshalCached restores a resumed answer as the config.WifiConfig
+// Generate returns, rather than the map[string]interface{} a plain
+// json.Unmarshal into interface{} would produce.
+func (wifiAsset) UnmarshalCached(data []byte) (interface{}, error) {
+	if string(data) == "null" {
+		return nil, nil
+	}
+	var wifi config.WifiConfig
+	if err := json.Unmarshal(data, &wifi); err != nil {
+		return nil, err
+	}
+	return wifi, nil
+}
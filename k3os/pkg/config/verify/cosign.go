@@ -0,0 +1,89 @@
+package verify
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+)
+
+func init() {
+	Register(cosignBundleVerifier{})
+}
+
+// cosignBundleVerifier checks the signature embedded in a `cosign
+// sign-blob --bundle` output file. It only authenticates the signature
+// itself against the certificate or key embedded in the bundle; it
+// does not walk the Fulcio chain or check the Rekor inclusion proof,
+// so pubKey must pin a trusted PEM public key rather than rely on
+// keyless transparency-log trust.
+type cosignBundleVerifier struct{}
+
+func (cosignBundleVerifier) Scheme() string { return "cosign-bundle" }
+
+type cosignBundle struct {
+	Base64Signature string `json:"base64Signature"`
+	Cert            string `json:"cert"`
+}
+
+func (cosignBundleVerifier) Verify(data, sig []byte, pubKey string) error {
+	var bundle cosignBundle
+	if err := json.Unmarshal(sig, &bundle); err != nil {
+		return fmt.Errorf("parsing bundle: %v", err)
+	}
+
+	sigBytes, err := base64.StdEncoding.DecodeString(bundle.Base64Signature)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %v", err)
+	}
+
+	key, err := cosignPublicKey(bundle, pubKey)
+	if err != nil {
+		return err
+	}
+
+	digest := sha256.Sum256(data)
+
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest[:], sigBytes) {
+			return fmt.Errorf("signature does not match")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], sigBytes)
+	default:
+		return fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// cosignPublicKey resolves the key to verify against: an explicit PEM
+// public key passed via --cloud-config-pubkey takes precedence over the
+// certificate embedded in the bundle.
+func cosignPublicKey(bundle cosignBundle, pubKey string) (crypto.PublicKey, error) {
+	if pubKey != "" {
+		block, _ := pem.Decode([]byte(pubKey))
+		if block == nil {
+			return nil, fmt.Errorf("--cloud-config-pubkey is not PEM encoded")
+		}
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	}
+
+	if bundle.Cert == "" {
+		return nil, fmt.Errorf("bundle has no embedded certificate and no --cloud-config-pubkey was given")
+	}
+	block, _ := pem.Decode([]byte(bundle.Cert))
+	if block == nil {
+		return nil, fmt.Errorf("bundle certificate is not PEM encoded")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing bundle certificate: %v", err)
+	}
+	return cert.PublicKey, nil
+}
@@ -0,0 +1,81 @@
+package verify
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register(minisignVerifier{})
+}
+
+// minisignVerifier checks the pure-Ed25519 ("Ed") minisign signature
+// format: https://jedisct1.github.io/minisign/. The legacy prehashed
+// ("ED") variant is not supported.
+type minisignVerifier struct{}
+
+func (minisignVerifier) Scheme() string { return "minisign" }
+
+func (minisignVerifier) Verify(data, sig []byte, pubKey string) error {
+	key, err := decodeMinisignKey(pubKey)
+	if err != nil {
+		return fmt.Errorf("public key: %v", err)
+	}
+
+	sigBytes, err := decodeMinisignSignature(sig)
+	if err != nil {
+		return fmt.Errorf("signature: %v", err)
+	}
+
+	if !ed25519.Verify(key, data, sigBytes) {
+		return fmt.Errorf("signature does not match")
+	}
+	return nil
+}
+
+// decodeMinisignKey extracts the 32-byte Ed25519 public key from a
+// minisign public key string ("untrusted comment: ..." followed by a
+// base64 line of the form "Ed" + 8-byte key id + 32-byte key).
+func decodeMinisignKey(pubKey string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(firstNonCommentLine(pubKey))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != 42 || string(raw[:2]) != "Ed" {
+		return nil, fmt.Errorf("not a minisign Ed25519 public key")
+	}
+	return ed25519.PublicKey(raw[10:]), nil
+}
+
+// decodeMinisignSignature extracts the 64-byte Ed25519 signature from a
+// minisign .sig file (algorithm "Ed" + 8-byte key id + 64-byte
+// signature, followed by a trusted comment and global signature that
+// this minimal verifier does not check).
+func decodeMinisignSignature(sig []byte) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(firstNonCommentLine(string(sig)))
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != 74 || string(raw[:2]) != "Ed" {
+		return nil, fmt.Errorf("not a minisign Ed25519 signature")
+	}
+	return raw[10:], nil
+}
+
+// firstNonCommentLine returns the first line of s that isn't blank or
+// an "untrusted comment:"/"trusted comment:" header - the per-file
+// signature (or key) line, found on line 2 whether s is a two-line
+// public key or a four-line .sig file whose trailing global signature
+// this minimal verifier doesn't check.
+func firstNonCommentLine(s string) string {
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "untrusted comment:") || strings.HasPrefix(line, "trusted comment:") {
+			continue
+		}
+		return line
+	}
+	return ""
+}
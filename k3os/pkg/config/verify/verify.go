@@ -0,0 +1,46 @@
+// Package verify checks detached signatures over a downloaded
+// cloud-config before k3os trusts it, whether that happens once at
+// install time or again every time k3os.install.config_url is
+// re-fetched on upgrade.
+package verify
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verifier authenticates data against sig using whatever pubKey means
+// for its scheme: a minisign public key string, an ssh allowed-signers
+// line, or a PEM public key for a cosign bundle.
+type Verifier interface {
+	// Scheme names the signature format this Verifier understands,
+	// e.g. "minisign".
+	Scheme() string
+	Verify(data, sig []byte, pubKey string) error
+}
+
+var verifiers = map[string]Verifier{}
+
+// Register adds v to the set tried by Verify. Called from each
+// scheme's init().
+func Register(v Verifier) {
+	verifiers[v.Scheme()] = v
+}
+
+// Verify tries data/sig against every registered scheme and succeeds as
+// soon as one of them authenticates it against pubKey.
+func Verify(data, sig []byte, pubKey string) error {
+	if len(verifiers) == 0 {
+		return fmt.Errorf("no signature verifiers registered")
+	}
+
+	var errs []string
+	for _, v := range verifiers {
+		if err := v.Verify(data, sig, pubKey); err == nil {
+			return nil
+		} else {
+			errs = append(errs, fmt.Sprintf("%s: %v", v.Scheme(), err))
+		}
+	}
+	return fmt.Errorf("cloud-config signature did not verify under any known scheme: %s", strings.Join(errs, "; "))
+}
@@ -0,0 +1,34 @@
+package image
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/rancher/k3os/pkg/partition"
+)
+
+// DiskConfigurator is the original install target: partition
+// ctx.Device directly, either via a declarative ctx.Partitions layout
+// or the legacy /usr/sbin/k3os-install-<type> script.
+type DiskConfigurator struct{}
+
+func (DiskConfigurator) Configure(ctx *Context) error {
+	if len(ctx.Partitions) > 0 {
+		plan, err := partition.Build(ctx.Device, ctx.Partitions)
+		if err != nil {
+			return err
+		}
+		if ctx.DryRun {
+			fmt.Println(plan)
+			return nil
+		}
+		return partition.Apply(plan)
+	}
+
+	script := fmt.Sprintf("/usr/sbin/k3os-install-%s", ctx.InstallType)
+	if ctx.DryRun {
+		fmt.Printf("# would run: %s %s\n", script, ctx.Device)
+		return nil
+	}
+	return exec.Command(script, ctx.Device).Run()
+}
@@ -0,0 +1,52 @@
+// Package image lets the installer target more than a local block
+// device: the same partitioning and config-install steps that lay
+// k3os down on disk can instead produce an ISO, a raw or qcow2 cloud
+// image, or a PXE artifact bundle, by swapping in a different
+// Configurator.
+package image
+
+import "github.com/rancher/k3os/config"
+
+// Context carries everything a Configurator needs to produce its
+// target.
+type Context struct {
+	// Device is the local block device to partition. Only used by
+	// DiskConfigurator; image-building configurators create their own
+	// backing file instead.
+	Device string
+
+	// InstallType selects the legacy gptmbr/mbr/efi script when
+	// Partitions is empty.
+	InstallType string
+
+	// Partitions is the declarative layout to apply, if any. See
+	// config.Install.Partitions.
+	Partitions []config.PartitionSpec
+
+	// ConfigPath is the normalized cloud-config to hand to
+	// k3os-install-config once the target is partitioned.
+	ConfigPath string
+
+	// Output is the destination path for configurators that produce an
+	// artifact rather than writing to Device directly.
+	Output string
+
+	// DryRun prints the partitioning plan instead of running it.
+	DryRun bool
+}
+
+// Configurator lays k3os down on one kind of target: a local disk, an
+// ISO remaster, a raw or qcow2 image, or a PXE bundle.
+type Configurator interface {
+	Configure(ctx *Context) error
+}
+
+// Configurators maps each install --output-format value to the
+// Configurator that implements it.
+var Configurators = map[string]Configurator{
+	"disk":  DiskConfigurator{},
+	"iso":   ISOConfigurator{},
+	"qcow2": QCOW2Configurator{},
+	"raw":   RawConfigurator{},
+	"pxe":   PXEConfigurator{},
+}
@@ -0,0 +1,29 @@
+package image
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// isoConfigPath is where the baked-in cloud-config lands inside the
+// remastered ISO, matching config.SystemConfig's layout on an
+// installed system.
+const isoConfigPath = "/k3os/system/config.yaml"
+
+// ISOConfigurator remasters the k3os live ISO with ctx.ConfigPath baked
+// in, so it boots straight into an already-configured system instead of
+// needing one supplied at boot time.
+type ISOConfigurator struct{}
+
+func (ISOConfigurator) Configure(ctx *Context) error {
+	if ctx.Output == "" {
+		return fmt.Errorf("--output is required for --output-format=iso")
+	}
+
+	return exec.Command("xorriso",
+		"-indev", "/run/initramfs/live/k3os.iso",
+		"-outdev", ctx.Output,
+		"-map", ctx.ConfigPath, isoConfigPath,
+		"-boot_image", "any", "replay",
+	).Run()
+}
@@ -0,0 +1,71 @@
+package image
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+)
+
+// pxeArtifacts are bundled from the running live system into the PXE
+// archive alongside the cloud-config, so a PXE server can serve all
+// three from one file.
+var pxeArtifacts = []string{
+	"/k3os/system/kernel/current/vmlinuz",
+	"/k3os/system/kernel/current/k3os-initrd",
+}
+
+// PXEConfigurator bundles the kernel, initrd and cloud-config into a
+// single tar artifact a PXE server can serve, instead of writing to a
+// disk or image file at all.
+type PXEConfigurator struct{}
+
+func (PXEConfigurator) Configure(ctx *Context) error {
+	if ctx.Output == "" {
+		return fmt.Errorf("--output is required for --output-format=pxe")
+	}
+	if ctx.DryRun {
+		return nil
+	}
+
+	out, err := os.Create(ctx.Output)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	tw := tar.NewWriter(out)
+	defer tw.Close()
+
+	for _, path := range append(append([]string{}, pxeArtifacts...), ctx.ConfigPath) {
+		if err := addFileToTar(tw, path); err != nil {
+			return fmt.Errorf("adding %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+func addFileToTar(tw *tar.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = info.Name()
+
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, f)
+	return err
+}
@@ -0,0 +1,37 @@
+package image
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+)
+
+// QCOW2Configurator builds a raw image the same way RawConfigurator
+// does, then converts it to qcow2 - the format cloud platforms like
+// OpenStack and libvirt expect.
+type QCOW2Configurator struct{}
+
+func (QCOW2Configurator) Configure(ctx *Context) error {
+	if ctx.Output == "" {
+		return fmt.Errorf("--output is required for --output-format=qcow2")
+	}
+
+	raw, err := ioutil.TempFile("", "k3os-raw-*.img")
+	if err != nil {
+		return err
+	}
+	raw.Close()
+	defer os.Remove(raw.Name())
+
+	rawCtx := *ctx
+	rawCtx.Output = raw.Name()
+	if err := (RawConfigurator{}).Configure(&rawCtx); err != nil {
+		return err
+	}
+	if ctx.DryRun {
+		return nil
+	}
+
+	return exec.Command("qemu-img", "convert", "-f", "raw", "-O", "qcow2", raw.Name(), ctx.Output).Run()
+}
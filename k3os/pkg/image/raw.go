@@ -0,0 +1,55 @@
+package image
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/rancher/k3os/config"
+	"github.com/rancher/k3os/pkg/partition"
+)
+
+// defaultImageSizeMiB is used when the partition layout doesn't add up
+// to an explicit total (a partition with SizeMiB 0 takes "the rest of
+// the disk").
+const defaultImageSizeMiB = 4096
+
+// RawConfigurator builds a sparse raw disk image at ctx.Output and
+// applies ctx.Partitions to it the same way DiskConfigurator applies
+// them to a real block device - sgdisk and friends work the same
+// against a regular file.
+type RawConfigurator struct{}
+
+func (RawConfigurator) Configure(ctx *Context) error {
+	if ctx.Output == "" {
+		return fmt.Errorf("--output is required for --output-format=raw")
+	}
+
+	size := imageSizeMiB(ctx.Partitions)
+	if err := exec.Command("qemu-img", "create", "-f", "raw", ctx.Output, fmt.Sprintf("%dM", size)).Run(); err != nil {
+		return fmt.Errorf("allocating %s: %v", ctx.Output, err)
+	}
+
+	plan, err := partition.Build(ctx.Output, ctx.Partitions)
+	if err != nil {
+		return err
+	}
+	if ctx.DryRun {
+		fmt.Println(plan)
+		return nil
+	}
+	return partition.Apply(plan)
+}
+
+func imageSizeMiB(partitions []config.PartitionSpec) int {
+	total := 0
+	for _, part := range partitions {
+		if part.SizeMiB == 0 {
+			return defaultImageSizeMiB
+		}
+		total += part.SizeMiB
+	}
+	if total == 0 {
+		return defaultImageSizeMiB
+	}
+	return total
+}
@@ -0,0 +1,25 @@
+package partition
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Apply runs every step in plan in order, stopping at the first
+// failure.
+func Apply(plan *Plan) error {
+	for _, step := range plan.Steps {
+		cmd := exec.Command(step.Command, step.Args...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if step.Stdin != "" {
+			cmd.Stdin = strings.NewReader(step.Stdin)
+		}
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %v", step.Description, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,248 @@
+// Package partition resolves a declarative config.Install.Partitions
+// layout into the sgdisk/mkfs/cryptsetup/mdadm invocations needed to
+// put it on disk, instead of shelling out to the fixed
+// /usr/sbin/k3os-install-<type> scripts.
+package partition
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/rancher/k3os/config"
+)
+
+// Step is a single command the plan will run to realize one part of
+// the layout.
+type Step struct {
+	Description string
+	Command     string
+	Args        []string
+
+	// Stdin, when non-empty, is piped to Command instead of inherited
+	// from the process - used to feed a LUKS passphrase to cryptsetup
+	// via --key-file=- rather than its interactive TTY prompt.
+	Stdin string
+}
+
+// String renders the step as the command --dry-run prints, never the
+// Stdin passphrase.
+func (s Step) String() string {
+	return fmt.Sprintf("# %s\n%s %s", s.Description, s.Command, strings.Join(s.Args, " "))
+}
+
+// Plan is the ordered set of Steps needed to lay config.Install.Partitions
+// down on a device.
+type Plan struct {
+	Device string
+	Steps  []Step
+}
+
+// String renders the plan the way install --dry-run prints it: one
+// command per step, in the order it would run.
+func (p *Plan) String() string {
+	lines := make([]string, len(p.Steps))
+	for i, s := range p.Steps {
+		lines[i] = s.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Build resolves partitions into the steps needed to lay them down on
+// device: wipe the table, create each partition (mirroring it with
+// mdadm first if it has a RaidSpec), optionally LUKS2-encrypt it, then
+// format it and mount it at its MountPoint, if set.
+func Build(device string, partitions []config.PartitionSpec) (*Plan, error) {
+	if len(partitions) == 0 {
+		return nil, fmt.Errorf("no partitions in layout")
+	}
+
+	plan := &Plan{Device: device}
+	plan.Steps = append(plan.Steps, Step{
+		Description: "wipe existing partition table on " + device,
+		Command:     "sgdisk",
+		Args:        []string{"--zap-all", device},
+	})
+
+	for i, part := range partitions {
+		num := i + 1
+		if err := planPartition(plan, device, num, part); err != nil {
+			return nil, fmt.Errorf("partition %d (%s): %v", num, part.Label, err)
+		}
+	}
+
+	return plan, nil
+}
+
+func planPartition(plan *Plan, device string, num int, part config.PartitionSpec) error {
+	size := "0:0"
+	if part.SizeMiB > 0 {
+		size = fmt.Sprintf("0:+%dMiB", part.SizeMiB)
+	}
+
+	plan.Steps = append(plan.Steps, Step{
+		Description: fmt.Sprintf("create partition %d (%s)", num, part.Label),
+		Command:     "sgdisk",
+		Args: []string{
+			fmt.Sprintf("--new=%d:%s", num, size),
+			fmt.Sprintf("--change-name=%d:%s", num, part.Label),
+			device,
+		},
+	})
+
+	target := partitionDevice(device, num)
+
+	if part.RAID != nil {
+		mdName, err := planRaid(plan, device, num, part)
+		if err != nil {
+			return err
+		}
+		target = mdName
+	}
+
+	if part.Encrypted {
+		var err error
+		target, err = planEncrypt(plan, target, part)
+		if err != nil {
+			return err
+		}
+	}
+
+	plan.Steps = append(plan.Steps, Step{
+		Description: fmt.Sprintf("format %s as %s", target, part.FSType),
+		Command:     mkfsCommand(part.FSType),
+		Args:        []string{target},
+	})
+
+	if part.MountPoint != "" {
+		plan.Steps = append(plan.Steps, Step{
+			Description: fmt.Sprintf("mount %s at %s", target, part.MountPoint),
+			Command:     "mount",
+			Args:        []string{target, part.MountPoint},
+		})
+	}
+
+	return nil
+}
+
+// planRaid creates a matching partition on each RAID member and
+// assembles them with mdadm, returning the resulting /dev/md/<name>.
+func planRaid(plan *Plan, device string, num int, part config.PartitionSpec) (string, error) {
+	raid := part.RAID
+	if raid.Name == "" {
+		return "", fmt.Errorf("raid spec has no name")
+	}
+
+	size := "0:0"
+	if part.SizeMiB > 0 {
+		size = fmt.Sprintf("0:+%dMiB", part.SizeMiB)
+	}
+
+	members := []string{partitionDevice(device, num)}
+	for _, member := range raid.Members {
+		plan.Steps = append(plan.Steps, Step{
+			Description: "wipe existing partition table on " + member,
+			Command:     "sgdisk",
+			Args:        []string{"--zap-all", member},
+		})
+		plan.Steps = append(plan.Steps, Step{
+			Description: fmt.Sprintf("create matching RAID member partition on %s", member),
+			Command:     "sgdisk",
+			Args: []string{
+				fmt.Sprintf("--new=%d:%s", num, size),
+				fmt.Sprintf("--change-name=%d:%s", num, part.Label),
+				member,
+			},
+		})
+		members = append(members, partitionDevice(member, num))
+	}
+
+	mdDevice := "/dev/md/" + raid.Name
+	args := append([]string{
+		"--create", mdDevice,
+		fmt.Sprintf("--level=%d", raid.Level),
+		fmt.Sprintf("--raid-devices=%d", len(members)),
+	}, members...)
+
+	plan.Steps = append(plan.Steps, Step{
+		Description: fmt.Sprintf("assemble RAID%d array %s", raid.Level, mdDevice),
+		Command:     "mdadm",
+		Args:        args,
+	})
+
+	return mdDevice, nil
+}
+
+// planEncrypt LUKS2-formats target and opens it under /dev/mapper. LUKS2
+// always needs an initial passphrase keyslot: that's part.Passphrase
+// when set, or - for a TPM2-only layout - a passphrase generated here
+// and never surfaced outside the plan, since its only purpose is to
+// seed the keyslot that part.TPM2's systemd-cryptenroll then seals to
+// the TPM.
+func planEncrypt(plan *Plan, target string, part config.PartitionSpec) (string, error) {
+	mapperName := "luks-" + part.Label
+
+	passphrase := part.Passphrase
+	if passphrase == "" {
+		if !part.TPM2 {
+			return "", fmt.Errorf("partition %s is encrypted but has neither a passphrase nor tpm2 set", part.Label)
+		}
+		generated, err := randomPassphrase()
+		if err != nil {
+			return "", fmt.Errorf("generating a keyslot passphrase for %s: %v", part.Label, err)
+		}
+		passphrase = generated
+	}
+
+	plan.Steps = append(plan.Steps, Step{
+		Description: fmt.Sprintf("LUKS2-format %s", target),
+		Command:     "cryptsetup",
+		Args:        []string{"luksFormat", "--type", "luks2", "--batch-mode", "--key-file=-", target},
+		Stdin:       passphrase,
+	})
+
+	plan.Steps = append(plan.Steps, Step{
+		Description: fmt.Sprintf("open %s as /dev/mapper/%s", target, mapperName),
+		Command:     "cryptsetup",
+		Args:        []string{"luksOpen", "--key-file=-", target, mapperName},
+		Stdin:       passphrase,
+	})
+
+	if part.TPM2 {
+		plan.Steps = append(plan.Steps, Step{
+			Description: fmt.Sprintf("enroll a TPM2-sealed key for %s", target),
+			Command:     "systemd-cryptenroll",
+			Args:        []string{"--tpm2-device=auto", target},
+		})
+	}
+
+	return "/dev/mapper/" + mapperName, nil
+}
+
+// randomPassphrase returns a base64-encoded 32 random bytes, used to
+// seed a LUKS2 keyslot when the layout asks for TPM2 sealing without
+// an explicit passphrase.
+func randomPassphrase() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawStdEncoding.EncodeToString(buf), nil
+}
+
+func mkfsCommand(fsType string) string {
+	if fsType == "" {
+		fsType = "ext4"
+	}
+	return "mkfs." + fsType
+}
+
+// partitionDevice guesses the partition device node for a disk: nvme
+// and mmcblk devices separate the partition number with a "p".
+func partitionDevice(device string, num int) string {
+	if strings.HasPrefix(device, "/dev/nvme") || strings.HasPrefix(device, "/dev/mmcblk") {
+		return fmt.Sprintf("%sp%d", device, num)
+	}
+	return fmt.Sprintf("%s%d", device, num)
+}
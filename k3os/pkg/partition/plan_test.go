@@ -0,0 +1,102 @@
+package partition
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rancher/k3os/config"
+)
+
+// commands flattens plan into "command arg arg" lines, in order, so
+// tests can assert on the shape of the plan without caring about
+// Description text.
+func commands(plan *Plan) []string {
+	var out []string
+	for _, s := range plan.Steps {
+		out = append(out, s.Command+" "+strings.Join(s.Args, " "))
+	}
+	return out
+}
+
+func TestBuildRaidZapsEachMember(t *testing.T) {
+	plan, err := Build("/dev/sda", []config.PartitionSpec{
+		{
+			Label:  "root",
+			FSType: "ext4",
+			RAID: &config.RaidSpec{
+				Level:   1,
+				Name:    "root",
+				Members: []string{"/dev/sdb"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmds := commands(plan)
+	zaps := 0
+	for _, c := range cmds {
+		if c == "sgdisk --zap-all /dev/sdb" {
+			zaps++
+		}
+	}
+	if zaps != 1 {
+		t.Fatalf("expected /dev/sdb to be zapped before partitioning, got steps: %v", cmds)
+	}
+}
+
+func TestBuildEncryptedPassphraseOnly(t *testing.T) {
+	plan, err := Build("/dev/sda", []config.PartitionSpec{
+		{Label: "root", FSType: "ext4", Encrypted: true, Passphrase: "hunter2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range plan.Steps {
+		if s.Command == "systemd-cryptenroll" {
+			t.Fatalf("TPM2 was not requested, should not enroll a TPM2 key: %v", s)
+		}
+		if s.Command == "cryptsetup" && s.Stdin != "hunter2" {
+			t.Fatalf("cryptsetup step should be fed the passphrase on stdin, got %q", s.Stdin)
+		}
+	}
+}
+
+func TestBuildEncryptedTPM2(t *testing.T) {
+	plan, err := Build("/dev/sda", []config.PartitionSpec{
+		{Label: "root", FSType: "ext4", Encrypted: true, TPM2: true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sawEnroll, sawFormat bool
+	for _, s := range plan.Steps {
+		if s.Command == "systemd-cryptenroll" {
+			sawEnroll = true
+		}
+		if s.Command == "cryptsetup" {
+			sawFormat = true
+			if s.Stdin == "" {
+				t.Fatalf("TPM2-only layout should still seed a LUKS keyslot with generated key material, got empty Stdin: %v", s)
+			}
+		}
+	}
+	if !sawEnroll {
+		t.Fatal("expected a systemd-cryptenroll step when TPM2 is requested")
+	}
+	if !sawFormat {
+		t.Fatal("expected cryptsetup steps")
+	}
+}
+
+func TestBuildEncryptedNeitherPassphraseNorTPM2(t *testing.T) {
+	_, err := Build("/dev/sda", []config.PartitionSpec{
+		{Label: "root", FSType: "ext4", Encrypted: true},
+	})
+	if err == nil {
+		t.Fatal("expected an error: encrypted with no passphrase and no tpm2 has no key material")
+	}
+}